@@ -2,29 +2,277 @@ package openaix
 
 import (
 	"errors"
+	"net/http"
+	"net/url"
 	"os"
 
 	"github.com/sashabaranov/go-openai"
 )
 
-// ClientFromEnv creates an OpenAI client based on environment variables.
-// It's kept here to unify the OpenAI client initialization among different projects.
-func ClientFromEnv() (*openai.Client, error) {
-	var (
-		kind     = os.Getenv("OPENAI_TYPE")
-		endpoint = os.Getenv("OPENAI_ENDPOINT")
-		version  = os.Getenv("OPENAI_API_VERSION")
-		key      = os.Getenv("OPENAI_API_KEY")
-	)
-
-	switch kind {
+// Config collects the parameters needed to build an OpenAI client,
+// independent of how they were gathered (environment variables via
+// ClientFromEnv, or explicit Option values via NewClient).
+type Config struct {
+	Kind       string
+	Endpoint   string
+	BaseURL    string
+	APIKey     string
+	APIType    openai.APIType
+	APIVersion string
+	OrgID      string
+	ProjectID  string
+	ProxyURL   string
+	HTTPClient *http.Client
+}
+
+// Option configures a Config for NewClient.
+type Option func(*Config) error
+
+// WithKind sets the backend kind: "azure", "openai", "compatible", or
+// "openai-compatible".
+func WithKind(kind string) Option {
+	return func(c *Config) error {
+		c.Kind = kind
+		return nil
+	}
+}
+
+// WithEndpoint sets the Azure resource endpoint or OpenAI-compatible base
+// URL, mirroring OPENAI_ENDPOINT.
+func WithEndpoint(endpoint string) Option {
+	return func(c *Config) error {
+		c.Endpoint = endpoint
+		return nil
+	}
+}
+
+// WithBaseURL overrides the final openai.ClientConfig.BaseURL regardless of
+// kind, for callers that need to point an "openai" client at a mirror or
+// gateway without going through the "compatible" kind.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Config) error {
+		c.BaseURL = baseURL
+		return nil
+	}
+}
+
+// WithAPIKey sets the API key, mirroring OPENAI_API_KEY.
+func WithAPIKey(key string) Option {
+	return func(c *Config) error {
+		c.APIKey = key
+		return nil
+	}
+}
+
+// WithAPIType overrides the go-openai APIType (e.g. openai.APITypeAzure),
+// mirroring OPENAI_API_TYPE. Only consulted for kind=compatible/
+// openai-compatible; "azure" and "openai" already imply their own APIType
+// via DefaultAzureConfig/DefaultConfig.
+func WithAPIType(apiType openai.APIType) Option {
+	return func(c *Config) error {
+		c.APIType = apiType
+		return nil
+	}
+}
+
+// WithAPIVersion sets the Azure/compatible API version, mirroring
+// OPENAI_API_VERSION.
+func WithAPIVersion(version string) Option {
+	return func(c *Config) error {
+		c.APIVersion = version
+		return nil
+	}
+}
+
+// WithOrgID sets the OpenAI organization ID, mirroring OPENAI_ORG_ID.
+func WithOrgID(orgID string) Option {
+	return func(c *Config) error {
+		c.OrgID = orgID
+		return nil
+	}
+}
+
+// WithHTTPClient injects a custom *http.Client, e.g. one wrapping a
+// recording/replay http.RoundTripper in tests. It takes precedence over
+// WithProxy.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Config) error {
+		c.HTTPClient = client
+		return nil
+	}
+}
+
+// WithProxy routes the client through an HTTP(S) proxy, mirroring
+// OPENAI_PROXY_ENDPOINT/OPENAI_PROXY_URL. Ignored if WithHTTPClient is also
+// given.
+func WithProxy(proxyURL string) Option {
+	return func(c *Config) error {
+		c.ProxyURL = proxyURL
+		return nil
+	}
+}
+
+// NewClient builds an *openai.Client from the given Options. It's the
+// programmatic counterpart to ClientFromEnv, for library consumers that
+// need to build clients without relying on process environment, e.g.
+// services that talk to multiple OpenAI backends in one process.
+func NewClient(opts ...Option) (*openai.Client, error) {
+	var config Config
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	httpClient, err := config.httpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	switch config.Kind {
 	case "azure":
-		config := openai.DefaultAzureConfig(key, endpoint)
-		config.APIVersion = version
-		return openai.NewClientWithConfig(config), nil
+		clientConfig := openai.DefaultAzureConfig(config.APIKey, config.Endpoint)
+		clientConfig.APIVersion = config.APIVersion
+		clientConfig.OrgID = config.OrgID
+		if httpClient != nil {
+			clientConfig.HTTPClient = httpClient
+		}
+		if config.BaseURL != "" {
+			clientConfig.BaseURL = config.BaseURL
+		}
+		return openai.NewClientWithConfig(clientConfig), nil
 	case "openai":
-		return openai.NewClient(key), nil
+		if httpClient == nil && config.OrgID == "" && config.BaseURL == "" {
+			return openai.NewClient(config.APIKey), nil
+		}
+		clientConfig := openai.DefaultConfig(config.APIKey)
+		clientConfig.OrgID = config.OrgID
+		if httpClient != nil {
+			clientConfig.HTTPClient = httpClient
+		}
+		if config.BaseURL != "" {
+			clientConfig.BaseURL = config.BaseURL
+		}
+		return openai.NewClientWithConfig(clientConfig), nil
+	case "compatible", "openai-compatible":
+		if config.Endpoint == "" {
+			return nil, errors.New("openaix: endpoint is required for kind=" + config.Kind)
+		}
+		clientConfig := openai.DefaultConfig(placeholderAPIKeyIfEmpty(config.APIKey))
+		clientConfig.BaseURL = config.Endpoint
+		clientConfig.OrgID = config.OrgID
+		if config.APIType != "" {
+			clientConfig.APIType = config.APIType
+		}
+		if config.APIVersion != "" {
+			clientConfig.APIVersion = config.APIVersion
+		}
+		if httpClient != nil {
+			clientConfig.HTTPClient = httpClient
+		}
+		if config.BaseURL != "" {
+			clientConfig.BaseURL = config.BaseURL
+		}
+		return openai.NewClientWithConfig(clientConfig), nil
+	}
+
+	return nil, errors.New("openaix: unknown OPENAI_TYPE: " + config.Kind)
+}
+
+// ClientFromEnv creates an OpenAI client based on environment variables.
+// It's kept here to unify the OpenAI client initialization among different
+// projects. It's a thin wrapper around NewClient that collects the
+// well-known OPENAI_* environment variables into Options.
+func ClientFromEnv() (*openai.Client, error) {
+	opts := []Option{
+		WithKind(os.Getenv("OPENAI_TYPE")),
+		WithEndpoint(os.Getenv("OPENAI_ENDPOINT")),
+		WithAPIVersion(os.Getenv("OPENAI_API_VERSION")),
+		WithAPIKey(os.Getenv("OPENAI_API_KEY")),
+		WithOrgID(os.Getenv("OPENAI_ORG_ID")),
+	}
+
+	if apiType := os.Getenv("OPENAI_API_TYPE"); apiType != "" {
+		opts = append(opts, WithAPIType(openai.APIType(apiType)))
+	}
+
+	if projectID := os.Getenv("OPENAI_PROJECT_ID"); projectID != "" {
+		opts = append(opts, withProjectID(projectID))
+	}
+
+	if proxy := os.Getenv("OPENAI_PROXY_ENDPOINT"); proxy != "" {
+		opts = append(opts, WithProxy(proxy))
+	} else if proxy := os.Getenv("OPENAI_PROXY_URL"); proxy != "" {
+		opts = append(opts, WithProxy(proxy))
+	}
+
+	return NewClient(opts...)
+}
+
+// withProjectID sets the OpenAI project ID, mirroring OPENAI_PROJECT_ID.
+// Unexported: go-openai's ClientConfig has no dedicated field for it, so it
+// can only be honored via the derived HTTP transport, not as a first-class
+// Option alongside WithOrgID.
+func withProjectID(projectID string) Option {
+	return func(c *Config) error {
+		c.ProjectID = projectID
+		return nil
 	}
+}
+
+// placeholderAPIKey is substituted for OPENAI_API_KEY when targeting an
+// OpenAI-compatible backend (e.g. a local Ollama server) that doesn't
+// require authentication, since go-openai rejects an empty key.
+const placeholderAPIKey = "sk-placeholder"
+
+func placeholderAPIKeyIfEmpty(key string) string {
+	if key == "" {
+		return placeholderAPIKey
+	}
+	return key
+}
+
+// httpClient resolves the *http.Client implied by the config: an explicit
+// WithHTTPClient wins outright, otherwise one is built from ProxyURL and
+// ProjectID as needed. It returns a nil client when neither is set, so
+// callers can fall back to go-openai's defaults unchanged.
+func (c *Config) httpClient() (*http.Client, error) {
+	if c.HTTPClient != nil {
+		return c.HTTPClient, nil
+	}
+
+	if c.ProxyURL == "" && c.ProjectID == "" {
+		return nil, nil
+	}
+
+	var transport http.RoundTripper = http.DefaultTransport
+	if c.ProxyURL != "" {
+		proxyURL, err := url.Parse(c.ProxyURL)
+		if err != nil {
+			return nil, errors.New("openaix: invalid proxy URL: " + err.Error())
+		}
+		if proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, errors.New("openaix: invalid proxy URL: missing scheme or host: " + c.ProxyURL)
+		}
+		transport = &http.Transport{Proxy: http.ProxyURL(proxyURL)}
+	}
+
+	if c.ProjectID != "" {
+		transport = &projectHeaderTransport{base: transport, projectID: c.ProjectID}
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// projectHeaderTransport attaches OPENAI_PROJECT_ID to outgoing requests for
+// OpenAI accounts that scope API keys to a project.
+type projectHeaderTransport struct {
+	base      http.RoundTripper
+	projectID string
+}
 
-	return nil, errors.New("openaix: unknown OPENAI_TYPE: " + kind)
+func (t *projectHeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("OpenAI-Project", t.projectID)
+	return t.base.RoundTrip(req)
 }
@@ -0,0 +1,380 @@
+package openaix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Default Router tuning, used when the corresponding RouterOption isn't
+// given.
+const (
+	defaultFailureThreshold = 3
+	defaultCoolDown         = 30 * time.Second
+	defaultBaseBackoff      = 200 * time.Millisecond
+	defaultMaxBackoff       = 5 * time.Second
+)
+
+// RouterEndpoint is one backend in a Router: a pre-built client plus the
+// metadata the Router needs to balance and fail over across it.
+type RouterEndpoint struct {
+	Name   string
+	Weight int
+	Client *openai.Client
+}
+
+// RouterConfig controls Router-wide behavior.
+type RouterConfig struct {
+	Endpoints []RouterEndpoint
+
+	// FailureThreshold is the number of consecutive failures that trips a
+	// backend's circuit breaker, marking it unhealthy.
+	FailureThreshold int
+	// CoolDown is how long a tripped backend is skipped before it's given
+	// another (half-open) trial.
+	CoolDown time.Duration
+	// BaseBackoff and MaxBackoff bound the exponential backoff applied
+	// between failover attempts.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// RouterOption configures a RouterConfig for NewRouter.
+type RouterOption func(*RouterConfig) error
+
+// WithRouterEndpoint adds a backend to the router. weight <= 0 is treated
+// as 1.
+func WithRouterEndpoint(name string, weight int, client *openai.Client) RouterOption {
+	return func(c *RouterConfig) error {
+		c.Endpoints = append(c.Endpoints, RouterEndpoint{Name: name, Weight: weight, Client: client})
+		return nil
+	}
+}
+
+// WithFailureThreshold overrides the default consecutive-failure count
+// (3) that trips a backend's circuit breaker.
+func WithFailureThreshold(n int) RouterOption {
+	return func(c *RouterConfig) error {
+		c.FailureThreshold = n
+		return nil
+	}
+}
+
+// WithCoolDown overrides the default cool-down (30s) before a tripped
+// backend is retried.
+func WithCoolDown(d time.Duration) RouterOption {
+	return func(c *RouterConfig) error {
+		c.CoolDown = d
+		return nil
+	}
+}
+
+// WithBackoff overrides the default exponential backoff bounds (200ms,
+// capped at 5s) applied between failover attempts.
+func WithBackoff(base, max time.Duration) RouterOption {
+	return func(c *RouterConfig) error {
+		c.BaseBackoff = base
+		c.MaxBackoff = max
+		return nil
+	}
+}
+
+// Router balances chat completion, streaming, and embedding calls across
+// multiple *openai.Client backends, failing over on 429s, 5xxs, and
+// timeouts. It's useful for combining a primary Azure deployment with an
+// OpenAI fallback, or spreading load across multiple Azure regions.
+type Router struct {
+	cfg RouterConfig
+
+	// order lists each backend once per unit of weight, so a plain
+	// round-robin cursor over it yields weighted selection.
+	order  []*routerBackend
+	cursor uint64
+}
+
+type routerBackend struct {
+	name   string
+	client *openai.Client
+
+	mu              sync.Mutex
+	consecutiveFail int
+	openUntil       time.Time
+}
+
+// NewRouter builds a Router from the given Options. At least one
+// WithRouterEndpoint is required.
+func NewRouter(opts ...RouterOption) (*Router, error) {
+	cfg := RouterConfig{
+		FailureThreshold: defaultFailureThreshold,
+		CoolDown:         defaultCoolDown,
+		BaseBackoff:      defaultBaseBackoff,
+		MaxBackoff:       defaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return nil, err
+		}
+	}
+	if len(cfg.Endpoints) == 0 {
+		return nil, errors.New("openaix: router requires at least one backend")
+	}
+
+	var order []*routerBackend
+	for _, ep := range cfg.Endpoints {
+		if ep.Client == nil {
+			return nil, errors.New("openaix: router backend " + ep.Name + " has a nil client")
+		}
+		weight := ep.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		b := &routerBackend{name: ep.Name, client: ep.Client}
+		for i := 0; i < weight; i++ {
+			order = append(order, b)
+		}
+	}
+
+	return &Router{cfg: cfg, order: order}, nil
+}
+
+// RouterFromEnv builds a Router from OPENAI_ENDPOINTS, a JSON array of
+// backend specs, e.g.:
+//
+//	[
+//	  {"name": "azure-eastus", "weight": 2, "kind": "azure", "endpoint": "https://eastus.openai.azure.com", "api_key": "..."},
+//	  {"name": "openai-fallback", "kind": "openai", "api_key": "..."}
+//	]
+//
+// Each spec is built via NewClient and accepts these counterparts of the
+// ClientFromEnv variables: kind, endpoint, api_key, api_version, org_id,
+// project_id, proxy_url. OPENAI_API_TYPE has no spec field.
+func RouterFromEnv() (*Router, error) {
+	raw := os.Getenv("OPENAI_ENDPOINTS")
+	if raw == "" {
+		return nil, errors.New("openaix: OPENAI_ENDPOINTS is not set")
+	}
+
+	var specs []routerEndpointSpec
+	if err := json.Unmarshal([]byte(raw), &specs); err != nil {
+		return nil, errors.New("openaix: invalid OPENAI_ENDPOINTS: " + err.Error())
+	}
+	if len(specs) == 0 {
+		return nil, errors.New("openaix: OPENAI_ENDPOINTS must list at least one backend")
+	}
+
+	opts := make([]RouterOption, 0, len(specs))
+	for _, spec := range specs {
+		clientOpts := []Option{WithKind(spec.Kind), WithEndpoint(spec.Endpoint), WithAPIKey(spec.APIKey)}
+		if spec.APIVersion != "" {
+			clientOpts = append(clientOpts, WithAPIVersion(spec.APIVersion))
+		}
+		if spec.OrgID != "" {
+			clientOpts = append(clientOpts, WithOrgID(spec.OrgID))
+		}
+		if spec.ProjectID != "" {
+			clientOpts = append(clientOpts, withProjectID(spec.ProjectID))
+		}
+		if spec.ProxyURL != "" {
+			clientOpts = append(clientOpts, WithProxy(spec.ProxyURL))
+		}
+
+		client, err := NewClient(clientOpts...)
+		if err != nil {
+			return nil, errors.New("openaix: backend " + spec.Name + ": " + err.Error())
+		}
+
+		name := spec.Name
+		if name == "" {
+			name = spec.Endpoint
+		}
+		opts = append(opts, WithRouterEndpoint(name, spec.Weight, client))
+	}
+
+	return NewRouter(opts...)
+}
+
+type routerEndpointSpec struct {
+	Name       string `json:"name"`
+	Weight     int    `json:"weight"`
+	Kind       string `json:"kind"`
+	Endpoint   string `json:"endpoint"`
+	APIKey     string `json:"api_key"`
+	APIVersion string `json:"api_version"`
+	OrgID      string `json:"org_id"`
+	ProjectID  string `json:"project_id"`
+	ProxyURL   string `json:"proxy_url"`
+}
+
+// CreateChatCompletion fails over across backends on 429/5xx/timeout
+// errors, retrying with exponential backoff.
+func (r *Router) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	var resp openai.ChatCompletionResponse
+	err := r.do(ctx, func(client *openai.Client) error {
+		var err error
+		resp, err = client.CreateChatCompletion(ctx, request)
+		return err
+	})
+	return resp, err
+}
+
+// CreateChatCompletionStream fails over across backends the same way as
+// CreateChatCompletion. Retries only happen while opening the stream: a
+// failure is only retryable here if go-openai hasn't yet handed back a
+// stream, i.e. before the first chunk is received. Once a stream is
+// returned successfully, the Router does not retry reads from it, so a
+// stream is never double-started against a second backend.
+func (r *Router) CreateChatCompletionStream(ctx context.Context, request openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error) {
+	var stream *openai.ChatCompletionStream
+	err := r.do(ctx, func(client *openai.Client) error {
+		var err error
+		stream, err = client.CreateChatCompletionStream(ctx, request)
+		return err
+	})
+	return stream, err
+}
+
+// CreateEmbeddings fails over across backends the same way as
+// CreateChatCompletion.
+func (r *Router) CreateEmbeddings(ctx context.Context, request openai.EmbeddingRequestConverter) (openai.EmbeddingResponse, error) {
+	var resp openai.EmbeddingResponse
+	err := r.do(ctx, func(client *openai.Client) error {
+		var err error
+		resp, err = client.CreateEmbeddings(ctx, request)
+		return err
+	})
+	return resp, err
+}
+
+// do runs call against a healthy backend, failing over to the next one in
+// the rotation on a retryable error. It makes at most one attempt per
+// weighted slot (len(r.order)), so a backend with weight > 1 may be tried
+// more than once in a single call.
+func (r *Router) do(ctx context.Context, call func(*openai.Client) error) error {
+	maxAttempts := len(r.order)
+	if maxAttempts == 0 {
+		return errors.New("openaix: router has no backends")
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		b, ok := r.pick()
+		if !ok {
+			break
+		}
+
+		err := call(b.client)
+		if err == nil {
+			b.recordSuccess()
+			return nil
+		}
+
+		lastErr = err
+		if !isRetryableError(err) {
+			// A non-retryable error (e.g. a 400/401 from bad caller input)
+			// means the backend is reachable and answering; don't trip its
+			// breaker over traffic that was never going to succeed anywhere.
+			b.recordSuccess()
+			return err
+		}
+		b.recordFailure(r.cfg.FailureThreshold, r.cfg.CoolDown)
+		if attempt < maxAttempts-1 && !r.sleepBackoff(ctx, attempt) {
+			return err
+		}
+	}
+
+	if lastErr != nil {
+		return lastErr
+	}
+	return errors.New("openaix: no healthy backend available")
+}
+
+// pick returns the next healthy backend in round-robin order, skipping
+// circuit-broken ones.
+func (r *Router) pick() (*routerBackend, bool) {
+	n := len(r.order)
+	for i := 0; i < n; i++ {
+		idx := int(atomic.AddUint64(&r.cursor, 1)-1) % n
+		b := r.order[idx]
+		if b.healthy(r.cfg.FailureThreshold) {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+func (r *Router) sleepBackoff(ctx context.Context, attempt int) bool {
+	delay := r.cfg.BaseBackoff * time.Duration(1<<uint(attempt))
+	if r.cfg.MaxBackoff > 0 && delay > r.cfg.MaxBackoff {
+		delay = r.cfg.MaxBackoff
+	}
+	if delay <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (b *routerBackend) healthy(threshold int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.consecutiveFail < threshold {
+		return true
+	}
+	return !b.openUntil.IsZero() && !time.Now().Before(b.openUntil)
+}
+
+func (b *routerBackend) recordFailure(threshold int, coolDown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutiveFail++
+	if b.consecutiveFail >= threshold {
+		b.openUntil = time.Now().Add(coolDown)
+	}
+}
+
+func (b *routerBackend) recordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutiveFail = 0
+	b.openUntil = time.Time{}
+}
+
+// isRetryableError reports whether err warrants trying the next backend:
+// a 429, a 5xx, or a timeout.
+func isRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode == http.StatusTooManyRequests || apiErr.HTTPStatusCode >= http.StatusInternalServerError
+	}
+
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode == http.StatusTooManyRequests || reqErr.HTTPStatusCode >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return netErr.Timeout()
+	}
+
+	return errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,230 @@
+package openaix
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// fakeBackend is an httptest server standing in for an OpenAI-compatible
+// backend, plus the *openai.Client pointed at it and a request counter so
+// tests can assert how many times each backend was actually hit.
+type fakeBackend struct {
+	server *httptest.Server
+	client *openai.Client
+	hits   int32
+}
+
+func newFakeBackend(t *testing.T, handler http.HandlerFunc) *fakeBackend {
+	t.Helper()
+	b := &fakeBackend{}
+	b.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&b.hits, 1)
+		handler(w, r)
+	}))
+	t.Cleanup(b.server.Close)
+
+	cfg := openai.DefaultConfig("test-key")
+	cfg.BaseURL = b.server.URL + "/v1"
+	b.client = openai.NewClientWithConfig(cfg)
+	return b
+}
+
+func (b *fakeBackend) hitCount() int {
+	return int(atomic.LoadInt32(&b.hits))
+}
+
+func jsonErrorHandler(status int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"error":{"message":"boom","type":"server_error","code":""}}`)
+	}
+}
+
+func chatCompletionOKHandler(content string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"id":"1","object":"chat.completion","created":1,"model":"gpt-3.5-turbo",
+			"choices":[{"index":0,"message":{"role":"assistant","content":%q},"finish_reason":"stop"}]}`, content)
+	}
+}
+
+const testRequestTimeout = 2 * time.Second
+
+func testChatRequest() openai.ChatCompletionRequest {
+	return openai.ChatCompletionRequest{
+		Model:    "gpt-3.5-turbo",
+		Messages: []openai.ChatCompletionMessage{{Role: "user", Content: "hi"}},
+	}
+}
+
+func newTestRouter(t *testing.T, backends ...*fakeBackend) *Router {
+	t.Helper()
+	opts := make([]RouterOption, 0, len(backends)+1)
+	for i, b := range backends {
+		opts = append(opts, WithRouterEndpoint(fmt.Sprintf("backend-%d", i), 1, b.client))
+	}
+	opts = append(opts, WithFailureThreshold(2), WithCoolDown(time.Minute), WithBackoff(time.Millisecond, 5*time.Millisecond))
+
+	r, err := NewRouter(opts...)
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+	return r
+}
+
+func TestRouter_FailoverOnRetryableError(t *testing.T) {
+	primary := newFakeBackend(t, jsonErrorHandler(http.StatusInternalServerError))
+	fallback := newFakeBackend(t, chatCompletionOKHandler("from fallback"))
+	r := newTestRouter(t, primary, fallback)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testRequestTimeout)
+	defer cancel()
+
+	resp, err := r.CreateChatCompletion(ctx, testChatRequest())
+	if err != nil {
+		t.Fatalf("CreateChatCompletion: %v", err)
+	}
+	if got := resp.Choices[0].Message.Content; got != "from fallback" {
+		t.Errorf("content = %q, want %q", got, "from fallback")
+	}
+	if primary.hitCount() != 1 {
+		t.Errorf("primary hit count = %d, want 1", primary.hitCount())
+	}
+	if fallback.hitCount() != 1 {
+		t.Errorf("fallback hit count = %d, want 1", fallback.hitCount())
+	}
+}
+
+func TestRouter_NonRetryableErrorStopsImmediately(t *testing.T) {
+	primary := newFakeBackend(t, jsonErrorHandler(http.StatusBadRequest))
+	fallback := newFakeBackend(t, chatCompletionOKHandler("from fallback"))
+	r := newTestRouter(t, primary, fallback)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testRequestTimeout)
+	defer cancel()
+
+	_, err := r.CreateChatCompletion(ctx, testChatRequest())
+	if err == nil {
+		t.Fatal("expected an error for a 400 response, got nil")
+	}
+	if primary.hitCount() != 1 {
+		t.Errorf("primary hit count = %d, want 1", primary.hitCount())
+	}
+	if fallback.hitCount() != 0 {
+		t.Errorf("fallback hit count = %d, want 0 (should not have been tried)", fallback.hitCount())
+	}
+}
+
+// TestRouter_NonRetryableErrorDoesNotTripBreaker covers the second review
+// comment on the router: a string of legitimate caller-side errors (400s)
+// must not open a backend's circuit breaker, since the backend itself is
+// healthy and reachable.
+func TestRouter_NonRetryableErrorDoesNotTripBreaker(t *testing.T) {
+	backend := newFakeBackend(t, jsonErrorHandler(http.StatusBadRequest))
+	r := newTestRouter(t, backend)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testRequestTimeout)
+	defer cancel()
+
+	// FailureThreshold is 2; five consecutive 400s would trip a breaker
+	// that (incorrectly) counted them as failures.
+	for i := 0; i < 5; i++ {
+		_, err := r.CreateChatCompletion(ctx, testChatRequest())
+		if err == nil {
+			t.Fatalf("call %d: expected a 400 error, got nil", i)
+		}
+		if apiErr, ok := asAPIError(err); ok {
+			if apiErr.HTTPStatusCode != http.StatusBadRequest {
+				t.Fatalf("call %d: unexpected error %v", i, err)
+			}
+		}
+	}
+	if backend.hitCount() != 5 {
+		t.Errorf("hit count = %d, want 5 (breaker must not have opened)", backend.hitCount())
+	}
+}
+
+func asAPIError(err error) (*openai.APIError, bool) {
+	apiErr, ok := err.(*openai.APIError)
+	return apiErr, ok
+}
+
+func TestRouter_CircuitBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	a := newFakeBackend(t, jsonErrorHandler(http.StatusInternalServerError))
+	b := newFakeBackend(t, jsonErrorHandler(http.StatusInternalServerError))
+	r := newTestRouter(t, a, b)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testRequestTimeout)
+	defer cancel()
+
+	// FailureThreshold is 2: two calls are enough to push both backends'
+	// consecutive failure counts to the threshold and open their breakers.
+	for i := 0; i < 2; i++ {
+		if _, err := r.CreateChatCompletion(ctx, testChatRequest()); err == nil {
+			t.Fatalf("call %d: expected an error", i)
+		}
+	}
+	hitsAfterTripping := a.hitCount() + b.hitCount()
+
+	_, err := r.CreateChatCompletion(ctx, testChatRequest())
+	if err == nil {
+		t.Fatal("expected an error once both backends are circuit-broken")
+	}
+	if got, want := err.Error(), "no healthy backend available"; got != "openaix: "+want {
+		t.Errorf("error = %q, want %q", got, "openaix: "+want)
+	}
+	if got := a.hitCount() + b.hitCount(); got != hitsAfterTripping {
+		t.Errorf("backends were hit again after their breakers opened: %d -> %d", hitsAfterTripping, got)
+	}
+}
+
+func TestRouter_StreamNotDoubleStartedOnRetry(t *testing.T) {
+	primary := newFakeBackend(t, jsonErrorHandler(http.StatusServiceUnavailable))
+	fallback := newFakeBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		flusher := w.(http.Flusher)
+		fmt.Fprintf(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"created\":1,\"model\":\"gpt-3.5-turbo\","+
+			"\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\"},\"finish_reason\":\"\"}]}\n\n")
+		flusher.Flush()
+		fmt.Fprintf(w, "data: [DONE]\n\n")
+		flusher.Flush()
+	})
+	r := newTestRouter(t, primary, fallback)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testRequestTimeout)
+	defer cancel()
+
+	stream, err := r.CreateChatCompletionStream(ctx, testChatRequest())
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream: %v", err)
+	}
+	defer stream.Close()
+
+	chunk, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("stream.Recv: %v", err)
+	}
+	if got := chunk.Choices[0].Delta.Content; got != "hi" {
+		t.Errorf("chunk content = %q, want %q", got, "hi")
+	}
+
+	// The failed open attempt against primary must not leave a second,
+	// concurrently-opened stream against fallback: primary is hit once
+	// (the failed open) and fallback is hit exactly once too, never twice.
+	if primary.hitCount() != 1 {
+		t.Errorf("primary hit count = %d, want 1", primary.hitCount())
+	}
+	if fallback.hitCount() != 1 {
+		t.Errorf("fallback hit count = %d, want 1", fallback.hitCount())
+	}
+}